@@ -0,0 +1,318 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	reqContext "context"
+	"crypto/rand"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+
+	ab "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// Signer signs the seek payloads that BlockDeliverer (and Orderer.Tail) wrap in a
+// fab.SignedEnvelope before sending them to the ordering service.
+type Signer interface {
+	// Sign returns the signature over msg.
+	Sign(msg []byte) ([]byte, error)
+	// Serialize returns the identity to place in the envelope's creator field.
+	Serialize() ([]byte, error)
+}
+
+// defaultMaxBlockNumber is used as the stop position for an open-ended, keep-alive deliver
+// stream: the ordering service will keep the stream open and push new blocks as they are
+// created rather than closing it once this (practically unreachable) block number is hit.
+const defaultMaxBlockNumber = uint64(math.MaxUint64)
+
+func specifiedSeekPosition(blockNum uint64) *ab.SeekPosition {
+	return &ab.SeekPosition{
+		Type: &ab.SeekPosition_Specified{
+			Specified: &ab.SeekSpecified{Number: blockNum},
+		},
+	}
+}
+
+// buildSeekEnvelope constructs and signs a DELIVER_SEEK_INFO envelope requesting blocks
+// start through stop from channelID's ordering service.
+func buildSeekEnvelope(channelID string, start, stop *ab.SeekPosition, behavior ab.SeekInfo_SeekBehavior, signer Signer) (*fab.SignedEnvelope, error) {
+	creator, err := signer.Serialize()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to serialize signing identity")
+	}
+
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	timestamp := ptypes.TimestampNow()
+
+	channelHeader, err := proto.Marshal(&common.ChannelHeader{
+		Type:      int32(common.HeaderType_DELIVER_SEEK_INFO),
+		ChannelId: channelID,
+		Timestamp: timestamp,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal channel header")
+	}
+
+	signatureHeader, err := proto.Marshal(&common.SignatureHeader{
+		Creator: creator,
+		Nonce:   nonce,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal signature header")
+	}
+
+	seekInfo, err := proto.Marshal(&ab.SeekInfo{
+		Start:    start,
+		Stop:     stop,
+		Behavior: behavior,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal seek info")
+	}
+
+	payload, err := proto.Marshal(&common.Payload{
+		Header: &common.Header{
+			ChannelHeader:   channelHeader,
+			SignatureHeader: signatureHeader,
+		},
+		Data: seekInfo,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal payload")
+	}
+
+	signature, err := signer.Sign(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign seek payload")
+	}
+
+	return &fab.SignedEnvelope{Payload: payload, Signature: signature}, nil
+}
+
+// BlockDelivererOption describes a functional parameter for the NewBlockDeliverer constructor.
+type BlockDelivererOption func(*BlockDeliverer)
+
+// WithCheckpointer configures the Checkpointer a BlockDeliverer uses to persist the last
+// block number it has successfully delivered. The default is an in-memory Checkpointer.
+func WithCheckpointer(checkpointer Checkpointer) BlockDelivererOption {
+	return func(d *BlockDeliverer) {
+		d.checkpointer = checkpointer
+	}
+}
+
+// WithReconnectBackoff configures the backoff applied between reconnect attempts, as a
+// function of the current consecutive-failure count.
+func WithReconnectBackoff(backoff func(attempt int) time.Duration) BlockDelivererOption {
+	return func(d *BlockDeliverer) {
+		d.backoff = backoff
+	}
+}
+
+// WithOnError registers a hook invoked with every stream error BlockDeliverer recovers from
+// by reconnecting. It is not called when Stop is used to end the stream deliberately.
+func WithOnError(onError func(error)) BlockDelivererOption {
+	return func(d *BlockDeliverer) {
+		d.onError = onError
+	}
+}
+
+func defaultReconnectBackoff(attempt int) time.Duration {
+	d := time.Duration(attempt) * 500 * time.Millisecond
+	if d > 10*time.Second {
+		d = 10 * time.Second
+	}
+	return d
+}
+
+// BlockDeliverer is a resumable block delivery client built on top of Orderer.SendDeliver.
+// It tracks the last successfully processed block number and automatically reconnects,
+// resuming from the next block, when the underlying gRPC stream errors.
+type BlockDeliverer struct {
+	orderer      *Orderer
+	channelID    string
+	signer       Signer
+	checkpointer Checkpointer
+	backoff      func(attempt int) time.Duration
+	onError      func(error)
+
+	blocks  chan *common.Block
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	started bool
+	stopped bool
+}
+
+// NewBlockDeliverer creates a BlockDeliverer that streams blocks for channelID from o,
+// signing seek requests with signer.
+func NewBlockDeliverer(o *Orderer, channelID string, signer Signer, opts ...BlockDelivererOption) *BlockDeliverer {
+	d := &BlockDeliverer{
+		orderer:      o,
+		channelID:    channelID,
+		signer:       signer,
+		checkpointer: NewInMemoryCheckpointer(),
+		backoff:      defaultReconnectBackoff,
+		blocks:       make(chan *common.Block),
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Blocks returns the channel on which delivered blocks are published. It is closed when
+// the deliverer stops: via Stop, when the context passed to Start is done, or when the
+// ordering service ends the stream with a success status. Stream errors do not close it;
+// they are retried by reconnecting.
+func (d *BlockDeliverer) Blocks() <-chan *common.Block {
+	return d.blocks
+}
+
+// Start begins streaming blocks for the channel, starting at startBlock unless the
+// configured Checkpointer already has a checkpoint at or after startBlock on record, in which
+// case delivery resumes from checkpoint+1. It returns an error without starting the stream if Stop has
+// already been called, since the deliverer cannot be restarted once stopped.
+func (d *BlockDeliverer) Start(ctx reqContext.Context, startBlock uint64) error {
+	next := startBlock
+	if last, ok, err := d.checkpointer.LastBlock(d.channelID); err != nil {
+		return errors.Wrap(err, "failed to read checkpoint")
+	} else if ok && last+1 > next {
+		next = last + 1
+	}
+
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return errors.New("block deliverer already stopped")
+	}
+	d.started = true
+	d.mu.Unlock()
+
+	go d.run(ctx, next)
+	return nil
+}
+
+// Stop ends the delivery stream and waits for the background goroutine to exit. It is a
+// no-op if Start was never called, rather than blocking forever waiting for a run loop
+// that was never started. Once Stop has been called, a subsequent Start returns an error
+// instead of starting a new run loop.
+func (d *BlockDeliverer) Stop() {
+	d.once.Do(func() {
+		d.mu.Lock()
+		d.stopped = true
+		started := d.started
+		d.mu.Unlock()
+
+		close(d.stopCh)
+		if !started {
+			close(d.blocks)
+			close(d.doneCh)
+		}
+	})
+	<-d.doneCh
+}
+
+func (d *BlockDeliverer) run(ctx reqContext.Context, startBlock uint64) {
+	defer close(d.doneCh)
+	defer close(d.blocks)
+
+	next := startBlock
+	attempt := 0
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		prev := next
+		streamErr := d.deliver(ctx, next, &next)
+		if streamErr == nil {
+			// The ordering service closed the stream with a success status, or Stop or ctx
+			// ended delivery; either way there is nothing left to deliver.
+			return
+		}
+		if next != prev {
+			// The stream made progress before failing, so this is a fresh run of failures.
+			attempt = 0
+		}
+
+		select {
+		case <-d.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if d.onError != nil {
+			d.onError(streamErr)
+		}
+
+		attempt++
+		select {
+		case <-time.After(d.backoff(attempt)):
+		case <-d.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *BlockDeliverer) deliver(ctx reqContext.Context, startBlock uint64, next *uint64) error {
+	envelope, err := buildSeekEnvelope(d.channelID, specifiedSeekPosition(startBlock), specifiedSeekPosition(defaultMaxBlockNumber), ab.SeekInfo_BLOCK_UNTIL_READY, d.signer)
+	if err != nil {
+		return err
+	}
+
+	streamCtx, cancel := reqContext.WithCancel(ctx)
+	defer cancel()
+
+	responses, errs := d.orderer.SendDeliver(streamCtx, envelope)
+	for {
+		select {
+		case block, ok := <-responses:
+			if !ok {
+				return nil
+			}
+
+			*next = block.Header.Number + 1
+			if err := d.checkpointer.SetLastBlock(d.channelID, block.Header.Number); err != nil {
+				logger.Warnf("channel_id=%s block_num=%d msg=\"failed to persist checkpoint\" error=%q", d.channelID, block.Header.Number, err)
+			}
+
+			select {
+			case d.blocks <- block:
+			case <-d.stopCh:
+				return nil
+			case <-ctx.Done():
+				return nil
+			}
+		case err := <-errs:
+			return err
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	ab "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/orderer"
+)
+
+func TestBlockDelivererStopWithoutStartDoesNotDeadlock(t *testing.T) {
+	d := NewBlockDeliverer(nil, "mychannel", nil)
+
+	done := make(chan struct{})
+	go func() {
+		d.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop() deadlocked when Start() was never called")
+	}
+}
+
+func TestBlockDelivererStopIsIdempotent(t *testing.T) {
+	d := NewBlockDeliverer(nil, "mychannel", nil)
+
+	done := make(chan struct{})
+	go func() {
+		d.Stop()
+		d.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("calling Stop() twice deadlocked")
+	}
+}
+
+func TestBlockDelivererStartAfterStopReturnsError(t *testing.T) {
+	d := NewBlockDeliverer(nil, "mychannel", nil)
+
+	d.Stop()
+
+	if err := d.Start(context.Background(), 0); err == nil {
+		t.Fatal("expected Start to return an error after Stop has already been called")
+	}
+}
+
+func TestNewBlockDelivererDefaults(t *testing.T) {
+	d := NewBlockDeliverer(nil, "mychannel", nil)
+
+	if d.checkpointer == nil {
+		t.Fatal("expected a default in-memory Checkpointer")
+	}
+	if d.backoff == nil {
+		t.Fatal("expected a default reconnect backoff")
+	}
+}
+
+type fakeSigner struct {
+	creator      []byte
+	signature    []byte
+	serializeErr error
+	signErr      error
+}
+
+func (s *fakeSigner) Sign(msg []byte) ([]byte, error) {
+	if s.signErr != nil {
+		return nil, s.signErr
+	}
+	return s.signature, nil
+}
+
+func (s *fakeSigner) Serialize() ([]byte, error) {
+	if s.serializeErr != nil {
+		return nil, s.serializeErr
+	}
+	return s.creator, nil
+}
+
+func TestBuildSeekEnvelopeSignerSerializeError(t *testing.T) {
+	signer := &fakeSigner{serializeErr: errors.New("no identity")}
+
+	_, err := buildSeekEnvelope("mychannel", specifiedSeekPosition(0), specifiedSeekPosition(defaultMaxBlockNumber), ab.SeekInfo_BLOCK_UNTIL_READY, signer)
+	if err == nil {
+		t.Fatal("expected an error when the signer fails to serialize the identity")
+	}
+}
+
+func TestBuildSeekEnvelopeSignError(t *testing.T) {
+	signer := &fakeSigner{creator: []byte("creator"), signErr: errors.New("signing failed")}
+
+	_, err := buildSeekEnvelope("mychannel", specifiedSeekPosition(0), specifiedSeekPosition(defaultMaxBlockNumber), ab.SeekInfo_BLOCK_UNTIL_READY, signer)
+	if err == nil {
+		t.Fatal("expected an error when the signer fails to sign the payload")
+	}
+}
+
+func TestBuildSeekEnvelopeSuccess(t *testing.T) {
+	signer := &fakeSigner{creator: []byte("creator"), signature: []byte("signature")}
+
+	envelope, err := buildSeekEnvelope("mychannel", specifiedSeekPosition(5), specifiedSeekPosition(defaultMaxBlockNumber), ab.SeekInfo_BLOCK_UNTIL_READY, signer)
+	if err != nil {
+		t.Fatalf("buildSeekEnvelope failed: %s", err)
+	}
+	if len(envelope.Payload) == 0 {
+		t.Fatal("expected a non-empty payload")
+	}
+	if string(envelope.Signature) != "signature" {
+		t.Fatalf("expected the signer's signature to be used verbatim, got %q", envelope.Signature)
+	}
+}
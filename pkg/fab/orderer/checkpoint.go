@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Checkpointer persists the last block number successfully delivered for a channel so that
+// a BlockDeliverer can resume from where it left off, including across process restarts.
+type Checkpointer interface {
+	// LastBlock returns the last checkpointed block number for channelID. The second return
+	// value is false if no checkpoint has been recorded yet.
+	LastBlock(channelID string) (uint64, bool, error)
+
+	// SetLastBlock records blockNum as the last successfully processed block for channelID.
+	SetLastBlock(channelID string, blockNum uint64) error
+}
+
+// inMemoryCheckpointer is the default Checkpointer. Checkpoints do not survive a process
+// restart.
+type inMemoryCheckpointer struct {
+	mu     sync.RWMutex
+	blocks map[string]uint64
+}
+
+// NewInMemoryCheckpointer returns a Checkpointer that keeps checkpoints in memory only.
+func NewInMemoryCheckpointer() Checkpointer {
+	return &inMemoryCheckpointer{blocks: map[string]uint64{}}
+}
+
+func (c *inMemoryCheckpointer) LastBlock(channelID string) (uint64, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	blockNum, ok := c.blocks[channelID]
+	return blockNum, ok, nil
+}
+
+func (c *inMemoryCheckpointer) SetLastBlock(channelID string, blockNum uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blocks[channelID] = blockNum
+	return nil
+}
+
+// fileCheckpointer is a Checkpointer that persists checkpoints to a JSON file on disk, so a
+// BlockDeliverer can resume after a restart instead of replaying from the beginning of the
+// channel (or wherever the caller last hard-coded a start block).
+type fileCheckpointer struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileCheckpointer returns a Checkpointer backed by the JSON file at path. The file is
+// created by the first SetLastBlock if it does not already exist; LastBlock on a missing file
+// reports no checkpoint and does not create it.
+func NewFileCheckpointer(path string) Checkpointer {
+	return &fileCheckpointer{path: path}
+}
+
+func (c *fileCheckpointer) LastBlock(channelID string) (uint64, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	blocks, err := c.load()
+	if err != nil {
+		return 0, false, err
+	}
+	blockNum, ok := blocks[channelID]
+	return blockNum, ok, nil
+}
+
+func (c *fileCheckpointer) SetLastBlock(channelID string, blockNum uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	blocks, err := c.load()
+	if err != nil {
+		return err
+	}
+	blocks[channelID] = blockNum
+	return c.save(blocks)
+}
+
+func (c *fileCheckpointer) load() (map[string]uint64, error) {
+	raw, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return map[string]uint64{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read checkpoint file")
+	}
+
+	blocks := map[string]uint64{}
+	if len(raw) == 0 {
+		return blocks, nil
+	}
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil, errors.Wrap(err, "failed to parse checkpoint file")
+	}
+	return blocks, nil
+}
+
+// save writes blocks to c.path atomically: it writes to a temp file in the same directory
+// and renames it over c.path, so a crash mid-write can never leave a partially-written,
+// unparseable checkpoint file behind.
+func (c *fileCheckpointer) save(blocks map[string]uint64) error {
+	raw, err := json.Marshal(blocks)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal checkpoint file")
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp checkpoint file")
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to write temp checkpoint file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close temp checkpoint file")
+	}
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		return errors.Wrap(err, "failed to set checkpoint file permissions")
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return errors.Wrap(err, "failed to rename temp checkpoint file into place")
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	reqContext "context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+const defaultMaxFailures = 3
+
+// ordererClient is the subset of *Orderer's behavior OrdererGroup depends on. Breaking it
+// out lets tests exercise failover/selection logic against a fake instead of a real *Orderer.
+type ordererClient interface {
+	URL() string
+	SendBroadcast(ctx reqContext.Context, envelope *fab.SignedEnvelope) (*common.Status, error)
+	SendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelope) (chan *common.Block, chan error)
+}
+
+// EndpointSelector picks the next candidate endpoint to try from a set of orderer endpoints.
+// Implementations should skip endpoints that report themselves unavailable (quarantined) or
+// that have already been tried during the current call, given in tried.
+type EndpointSelector interface {
+	// Select returns the index, within endpoints, of the endpoint to try next. tried holds
+	// the indices already attempted during the current SendBroadcast/SendDeliver call and
+	// must not be returned again unless every endpoint has already been tried.
+	Select(endpoints []*endpoint, tried map[int]bool) int
+}
+
+// endpoint pairs an orderer client with the failure bookkeeping used to quarantine it after
+// repeated errors.
+type endpoint struct {
+	mu               sync.Mutex
+	orderer          ordererClient
+	failures         int
+	quarantinedUntil time.Time
+}
+
+func (e *endpoint) available(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return now.After(e.quarantinedUntil)
+}
+
+func (e *endpoint) recordSuccess() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures = 0
+	e.quarantinedUntil = time.Time{}
+}
+
+func (e *endpoint) recordFailure(maxFailures int, backoff func(failures int) time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures++
+	if e.failures >= maxFailures {
+		e.quarantinedUntil = time.Now().Add(backoff(e.failures))
+	}
+}
+
+// roundRobinSelector cycles through the endpoints in the order they were added, skipping
+// any that are currently quarantined.
+type roundRobinSelector struct {
+	mu   sync.Mutex
+	next int
+}
+
+// NewRoundRobinSelector returns an EndpointSelector that cycles through endpoints in order.
+func NewRoundRobinSelector() EndpointSelector {
+	return &roundRobinSelector{}
+}
+
+func (s *roundRobinSelector) Select(endpoints []*endpoint, tried map[int]bool) int {
+	s.mu.Lock()
+	start := s.next
+	s.next = (s.next + 1) % len(endpoints)
+	s.mu.Unlock()
+
+	now := time.Now()
+	// Prefer an available endpoint that hasn't been tried yet this call.
+	for i := 0; i < len(endpoints); i++ {
+		idx := (start + i) % len(endpoints)
+		if !tried[idx] && endpoints[idx].available(now) {
+			return idx
+		}
+	}
+	// Every available endpoint has already been tried this call; fall back to any
+	// untried one, quarantined or not, so a single call never retries the same endpoint
+	// twice while untried alternatives remain.
+	for i := 0; i < len(endpoints); i++ {
+		idx := (start + i) % len(endpoints)
+		if !tried[idx] {
+			return idx
+		}
+	}
+	return start
+}
+
+// priorityHealthSelector always prefers the first available endpoint in list order, only
+// falling through to a lower-priority endpoint once the higher-priority ones are quarantined.
+type priorityHealthSelector struct{}
+
+// NewPriorityHealthSelector returns an EndpointSelector that tries endpoints in the order
+// supplied to NewOrdererGroup, demoting an endpoint to the back of consideration only while
+// it is quarantined due to repeated failures.
+func NewPriorityHealthSelector() EndpointSelector {
+	return &priorityHealthSelector{}
+}
+
+func (s *priorityHealthSelector) Select(endpoints []*endpoint, tried map[int]bool) int {
+	now := time.Now()
+	for i, e := range endpoints {
+		if !tried[i] && e.available(now) {
+			return i
+		}
+	}
+	// Every available endpoint has already been tried this call; demote to the next
+	// untried one (even if quarantined) instead of handing back the same dead endpoint.
+	for i := range endpoints {
+		if !tried[i] {
+			return i
+		}
+	}
+	return 0
+}
+
+// randomSelector picks a pseudo-random available endpoint on every call.
+type randomSelector struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// NewRandomSelector returns an EndpointSelector that picks a random available endpoint.
+func NewRandomSelector() EndpointSelector {
+	return &randomSelector{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func (s *randomSelector) Select(endpoints []*endpoint, tried map[int]bool) int {
+	now := time.Now()
+	var candidates []int
+	for i, e := range endpoints {
+		if !tried[i] && e.available(now) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		for i := range endpoints {
+			if !tried[i] {
+				candidates = append(candidates, i)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = []int{0}
+	}
+
+	s.mu.Lock()
+	idx := candidates[s.r.Intn(len(candidates))]
+	s.mu.Unlock()
+	return idx
+}
+
+// OrdererGroup fronts a set of Orderer endpoints with a single client, transparently
+// failing over to the next endpoint (as chosen by an EndpointSelector) when one is
+// unreachable or repeatedly erroring.
+type OrdererGroup struct {
+	endpoints   []*endpoint
+	selector    EndpointSelector
+	maxFailures int
+	backoff     func(failures int) time.Duration
+}
+
+// GroupOption describes a functional parameter for the NewOrdererGroup constructor.
+type GroupOption func(*OrdererGroup) error
+
+// NewOrdererGroup creates an OrdererGroup over the given orderers. At least one orderer
+// must be supplied. The default selector is round-robin, and a quarantined endpoint is held
+// back for 2s per consecutive failure, capped at 30s.
+func NewOrdererGroup(orderers []*Orderer, opts ...GroupOption) (*OrdererGroup, error) {
+	clients := make([]ordererClient, len(orderers))
+	for i, o := range orderers {
+		clients[i] = o
+	}
+
+	return newOrdererGroup(clients, opts...)
+}
+
+// newOrdererGroup is the ordererClient-based constructor NewOrdererGroup wraps. It is
+// unexported so tests can supply fakes while the public API keeps dealing in *Orderer.
+func newOrdererGroup(clients []ordererClient, opts ...GroupOption) (*OrdererGroup, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("at least one orderer is required")
+	}
+
+	endpoints := make([]*endpoint, len(clients))
+	for i, c := range clients {
+		endpoints[i] = &endpoint{orderer: c}
+	}
+
+	g := &OrdererGroup{
+		endpoints:   endpoints,
+		selector:    NewRoundRobinSelector(),
+		maxFailures: defaultMaxFailures,
+		backoff:     defaultQuarantineBackoff,
+	}
+
+	for _, opt := range opts {
+		if err := opt(g); err != nil {
+			return nil, err
+		}
+	}
+
+	return g, nil
+}
+
+// WithSelector configures the EndpointSelector used to pick among the group's endpoints.
+func WithSelector(selector EndpointSelector) GroupOption {
+	return func(g *OrdererGroup) error {
+		g.selector = selector
+		return nil
+	}
+}
+
+// WithMaxFailures configures how many consecutive failures an endpoint tolerates before
+// it is quarantined.
+func WithMaxFailures(maxFailures int) GroupOption {
+	return func(g *OrdererGroup) error {
+		g.maxFailures = maxFailures
+		return nil
+	}
+}
+
+// WithQuarantineBackoff configures the backoff window an endpoint is quarantined for,
+// as a function of its current consecutive failure count.
+func WithQuarantineBackoff(backoff func(failures int) time.Duration) GroupOption {
+	return func(g *OrdererGroup) error {
+		g.backoff = backoff
+		return nil
+	}
+}
+
+func defaultQuarantineBackoff(failures int) time.Duration {
+	d := time.Duration(failures) * 2 * time.Second
+	if d > 30*time.Second {
+		d = 30 * time.Second
+	}
+	return d
+}
+
+// SendBroadcast sends the envelope to the ordering service, trying endpoints (as chosen by
+// the configured EndpointSelector) until one succeeds or all have been tried.
+func (g *OrdererGroup) SendBroadcast(ctx reqContext.Context, envelope *fab.SignedEnvelope) (*common.Status, error) {
+	var lastErr error
+	tried := make(map[int]bool, len(g.endpoints))
+	for attempt := 0; attempt < len(g.endpoints); attempt++ {
+		idx := g.selector.Select(g.endpoints, tried)
+		tried[idx] = true
+		e := g.endpoints[idx]
+
+		status, err := e.orderer.SendBroadcast(ctx, envelope)
+		if err == nil {
+			e.recordSuccess()
+			return status, nil
+		}
+
+		logger.Warnf("orderer_url=%s msg=\"broadcast failed, trying next endpoint\" error=%q", e.orderer.URL(), err)
+		e.recordFailure(g.maxFailures, g.backoff)
+		lastErr = err
+	}
+
+	return nil, errors.WithMessage(lastErr, "all orderer endpoints failed")
+}
+
+// SendDeliver sends a deliver request to the ordering service, trying endpoints (as chosen
+// by the configured EndpointSelector) until one accepts the request.
+func (g *OrdererGroup) SendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelope) (chan *common.Block, chan error) {
+	var lastErr error
+	tried := make(map[int]bool, len(g.endpoints))
+	for attempt := 0; attempt < len(g.endpoints); attempt++ {
+		idx := g.selector.Select(g.endpoints, tried)
+		tried[idx] = true
+		e := g.endpoints[idx]
+
+		responses, errs := e.orderer.SendDeliver(ctx, envelope)
+		select {
+		case err := <-errs:
+			logger.Warnf("orderer_url=%s msg=\"deliver failed, trying next endpoint\" error=%q", e.orderer.URL(), err)
+			e.recordFailure(g.maxFailures, g.backoff)
+			lastErr = err
+			continue
+		default:
+			e.recordSuccess()
+			return responses, errs
+		}
+	}
+
+	errs := make(chan error, 1)
+	errs <- errors.WithMessage(lastErr, "all orderer endpoints failed")
+	return nil, errs
+}
+
+// connKeyContextKey is the context key Orderer.conn uses to pass a connection pool cache key
+// (the target plus the secured flag, serverName and client certificate it was dialed with)
+// down to pooledConnector, so two Orderers that share a target but differ in any of those never
+// share a cached connection. The TLS CA certificate is not part of the key.
+type connKeyContextKey struct{}
+
+// withConnKey attaches the connection pool cache key to be used for a DialContext call.
+func withConnKey(ctx reqContext.Context, key string) reqContext.Context {
+	return reqContext.WithValue(ctx, connKeyContextKey{}, key)
+}
+
+// connKeyFromContext returns the cache key attached by withConnKey, falling back to target
+// (the pre-fix behavior) if none was set - e.g. a caller dialing through pooledConnector
+// directly rather than via Orderer.conn.
+func connKeyFromContext(ctx reqContext.Context, target string) string {
+	if key, ok := ctx.Value(connKeyContextKey{}).(string); ok && key != "" {
+		return key
+	}
+	return target
+}
+
+// pooledConnector is a connProvider that keeps a warm *grpc.ClientConn per (target, secured,
+// serverName, client certificate) tuple instead of dialing on every call and closing the
+// connection in ReleaseConn. This avoids repeated TLS handshakes against the same orderer
+// endpoint, while still dialing separately for Orderers that share a target but differ in
+// serverName or client certificate. Orderers that differ only in their TLS CA certificate
+// share a connection.
+type pooledConnector struct {
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// NewPooledConnector returns a connProvider suitable for passing to WithConnProvider that
+// reuses one *grpc.ClientConn per (target, TLS configuration) tuple for the lifetime of the
+// process.
+func NewPooledConnector() connProvider {
+	return &pooledConnector{conns: map[string]*grpc.ClientConn{}}
+}
+
+func (p *pooledConnector) DialContext(ctx reqContext.Context, target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	key := connKeyFromContext(ctx, target)
+
+	p.mu.Lock()
+	if conn, ok := p.conns[key]; ok {
+		p.mu.Unlock()
+		return conn, nil
+	}
+	p.mu.Unlock()
+
+	conn, err := grpc.DialContext(ctx, target, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing, ok := p.conns[key]; ok {
+		// lost the race with a concurrent dial for the same key; keep the winner.
+		conn.Close()
+		return existing, nil
+	}
+	p.conns[key] = conn
+	return conn, nil
+}
+
+// ReleaseConn is a no-op: pooled connections are kept warm for reuse rather than closed
+// after every call. Use Close to tear the pool down.
+func (p *pooledConnector) ReleaseConn(conn *grpc.ClientConn) {
+}
+
+// Close closes every pooled connection. It should be called once the OrdererGroup (or
+// Orderer) using this connector is no longer needed.
+func (p *pooledConnector) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var lastErr error
+	for key, conn := range p.conns {
+		if err := conn.Close(); err != nil {
+			lastErr = err
+		}
+		delete(p.conns, key)
+	}
+	return lastErr
+}
@@ -0,0 +1,179 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	reqContext "context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+type fakeOrdererClient struct {
+	url            string
+	broadcastErr   error
+	broadcastCalls int
+	deliverErr     error
+	deliverCalls   int
+}
+
+func (f *fakeOrdererClient) URL() string {
+	return f.url
+}
+
+func (f *fakeOrdererClient) SendBroadcast(ctx reqContext.Context, envelope *fab.SignedEnvelope) (*common.Status, error) {
+	f.broadcastCalls++
+	if f.broadcastErr != nil {
+		return nil, f.broadcastErr
+	}
+	status := common.Status_SUCCESS
+	return &status, nil
+}
+
+func (f *fakeOrdererClient) SendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelope) (chan *common.Block, chan error) {
+	f.deliverCalls++
+	responses := make(chan *common.Block)
+	errs := make(chan error, 1)
+	if f.deliverErr != nil {
+		errs <- f.deliverErr
+		return responses, errs
+	}
+	close(responses)
+	return responses, errs
+}
+
+func TestOrdererGroupSendBroadcastFailsOverWithPriorityHealthSelector(t *testing.T) {
+	dead := &fakeOrdererClient{url: "dead:7050", broadcastErr: errors.New("unreachable")}
+	healthy := &fakeOrdererClient{url: "healthy:7050"}
+
+	g, err := newOrdererGroup([]ordererClient{dead, healthy}, WithSelector(NewPriorityHealthSelector()))
+	if err != nil {
+		t.Fatalf("newOrdererGroup failed: %s", err)
+	}
+
+	status, err := g.SendBroadcast(reqContext.Background(), &fab.SignedEnvelope{})
+	if err != nil {
+		t.Fatalf("expected SendBroadcast to fail over to the healthy endpoint, got error: %s", err)
+	}
+	if status == nil || *status != common.Status_SUCCESS {
+		t.Fatalf("expected SUCCESS status, got %v", status)
+	}
+
+	// The dead endpoint's failure count (1) never reaches defaultMaxFailures (3), so without
+	// the tried-set fix the selector would keep returning index 0 for every attempt in this
+	// call and never reach the healthy endpoint.
+	if dead.broadcastCalls != 1 {
+		t.Fatalf("expected the dead endpoint to be tried exactly once per call, got %d", dead.broadcastCalls)
+	}
+	if healthy.broadcastCalls != 1 {
+		t.Fatalf("expected the healthy endpoint to be tried exactly once, got %d", healthy.broadcastCalls)
+	}
+}
+
+func TestOrdererGroupSendDeliverFailsOverWithPriorityHealthSelector(t *testing.T) {
+	dead := &fakeOrdererClient{url: "dead:7050", deliverErr: errors.New("unreachable")}
+	healthy := &fakeOrdererClient{url: "healthy:7050"}
+
+	g, err := newOrdererGroup([]ordererClient{dead, healthy}, WithSelector(NewPriorityHealthSelector()))
+	if err != nil {
+		t.Fatalf("newOrdererGroup failed: %s", err)
+	}
+
+	_, errs := g.SendDeliver(reqContext.Background(), &fab.SignedEnvelope{})
+	if err := <-errs; err != nil {
+		t.Fatalf("expected SendDeliver to fail over to the healthy endpoint, got error: %s", err)
+	}
+
+	if dead.deliverCalls != 1 {
+		t.Fatalf("expected the dead endpoint to be tried exactly once per call, got %d", dead.deliverCalls)
+	}
+	if healthy.deliverCalls != 1 {
+		t.Fatalf("expected the healthy endpoint to be tried exactly once, got %d", healthy.deliverCalls)
+	}
+}
+
+func TestOrdererGroupAllEndpointsFail(t *testing.T) {
+	first := &fakeOrdererClient{url: "one:7050", broadcastErr: errors.New("boom1")}
+	second := &fakeOrdererClient{url: "two:7050", broadcastErr: errors.New("boom2")}
+
+	g, err := newOrdererGroup([]ordererClient{first, second}, WithSelector(NewPriorityHealthSelector()))
+	if err != nil {
+		t.Fatalf("newOrdererGroup failed: %s", err)
+	}
+
+	if _, err := g.SendBroadcast(reqContext.Background(), &fab.SignedEnvelope{}); err == nil {
+		t.Fatal("expected an error when every endpoint fails")
+	}
+	if first.broadcastCalls != 1 || second.broadcastCalls != 1 {
+		t.Fatalf("expected each endpoint to be tried exactly once, got first=%d second=%d", first.broadcastCalls, second.broadcastCalls)
+	}
+}
+
+func TestPriorityHealthSelectorSkipsTriedIndices(t *testing.T) {
+	selector := NewPriorityHealthSelector()
+	endpoints := []*endpoint{{}, {}, {}}
+
+	tried := map[int]bool{}
+	for want := 0; want < len(endpoints); want++ {
+		got := selector.Select(endpoints, tried)
+		if got != want {
+			t.Fatalf("attempt %d: expected endpoint %d, got %d", want, want, got)
+		}
+		tried[got] = true
+	}
+}
+
+func TestRoundRobinSelectorSkipsTriedIndices(t *testing.T) {
+	selector := NewRoundRobinSelector()
+	endpoints := []*endpoint{{}, {}, {}}
+
+	tried := map[int]bool{}
+	seen := map[int]bool{}
+	for i := 0; i < len(endpoints); i++ {
+		got := selector.Select(endpoints, tried)
+		if seen[got] {
+			t.Fatalf("endpoint %d was selected twice within the same call", got)
+		}
+		seen[got] = true
+		tried[got] = true
+	}
+}
+
+func TestPooledConnectorKeysByConnKeyNotTargetAlone(t *testing.T) {
+	p := NewPooledConnector().(*pooledConnector)
+
+	ctxA := withConnKey(reqContext.Background(), "target:7050|secured=true|serverName=a|clientCert=false")
+	ctxB := withConnKey(reqContext.Background(), "target:7050|secured=true|serverName=b|clientCert=false")
+
+	connA, err := p.DialContext(ctxA, "target:7050", grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("DialContext failed: %s", err)
+	}
+	defer connA.Close()
+
+	connASecond, err := p.DialContext(ctxA, "target:7050", grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("DialContext failed: %s", err)
+	}
+	if connA != connASecond {
+		t.Fatal("expected the same connection to be reused for an identical conn key")
+	}
+
+	connB, err := p.DialContext(ctxB, "target:7050", grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("DialContext failed: %s", err)
+	}
+	defer connB.Close()
+
+	if connA == connB {
+		t.Fatal("expected distinct connections for the same target but different TLS/serverName conn keys")
+	}
+}
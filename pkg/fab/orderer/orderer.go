@@ -8,7 +8,13 @@ package orderer
 
 import (
 	reqContext "context"
+	"crypto/sha256"
+	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -49,6 +55,19 @@ type Orderer struct {
 	secured              bool
 	allowInsecure        bool
 	connector            connProvider
+	clientCert           *tls.Certificate
+	clientCertProvider   func() (*tls.Certificate, error)
+	log                  Logger
+}
+
+// Logger is the logging interface used by the orderer package. It is satisfied by the SDK's
+// default logger as well as common third-party loggers (e.g. zap's SugaredLogger, logrus),
+// so callers can plug in their own logging backend via WithLogger.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
 }
 
 // Option describes a functional parameter for the New constructor
@@ -59,6 +78,7 @@ func New(config core.Config, opts ...Option) (*Orderer, error) {
 	orderer := &Orderer{
 		config:    config,
 		connector: &defConnector{},
+		log:       logger,
 	}
 
 	for _, opt := range opts {
@@ -81,9 +101,20 @@ func New(config core.Config, opts ...Option) (*Orderer, error) {
 		return nil, err
 	}
 
+	switch {
+	case orderer.clientCertProvider != nil:
+		// GetClientCertificate is invoked on every handshake, so deployments can rotate the
+		// client cert without rebuilding the Orderer.
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return orderer.clientCertProvider()
+		}
+	case orderer.clientCert != nil:
+		tlsConfig.Certificates = []tls.Certificate{*orderer.clientCert}
+	}
+
 	orderer.grpcDialOption = grpcOpts
 	orderer.transportCredentials = credentials.NewTLS(tlsConfig)
-	logger.Errorf("orderer.url [%s]", orderer.url)
+	orderer.log.Debugf("orderer_url=%s msg=\"configuring orderer\"", orderer.url)
 	orderer.secured = urlutil.AttemptSecured(orderer.url)
 	orderer.url = urlutil.ToAddress(orderer.url)
 
@@ -93,13 +124,27 @@ func New(config core.Config, opts ...Option) (*Orderer, error) {
 // WithURL is a functional option for the orderer.New constructor that configures the orderer's URL.
 func WithURL(url string) Option {
 	return func(o *Orderer) error {
-		logger.Errorf("url [%s]", url)
+		o.log.Debugf("orderer_url=%s msg=\"setting orderer url\"", url)
 		o.url = url
 
 		return nil
 	}
 }
 
+// WithLogger is a functional option for the orderer.New constructor that overrides the
+// default SDK logger, allowing callers to plug in zap, logrus, or any other Logger
+// implementation.
+func WithLogger(log Logger) Option {
+	return func(o *Orderer) error {
+		if log == nil {
+			return errors.New("logger cannot be nil")
+		}
+		o.log = log
+
+		return nil
+	}
+}
+
 // WithTLSCert is a functional option for the orderer.New constructor that configures the orderer's TLS certificate
 func WithTLSCert(tlsCACert *x509.Certificate) Option {
 	return func(o *Orderer) error {
@@ -118,6 +163,31 @@ func WithServerName(serverName string) Option {
 	}
 }
 
+// WithClientCert is a functional option for the orderer.New constructor that configures a static
+// client certificate to present during the mutual-TLS handshake.
+func WithClientCert(cert tls.Certificate) Option {
+	return func(o *Orderer) error {
+		o.clientCert = &cert
+
+		return nil
+	}
+}
+
+// WithClientCertProvider is a functional option for the orderer.New constructor that configures a
+// callback invoked on every TLS handshake to obtain the client certificate to present. Unlike
+// WithClientCert, this allows deployments to hot-rotate client certificates without rebuilding
+// the Orderer. Note that with a pooled connProvider (see NewPooledConnector), "every TLS
+// handshake" means every time a new connection is dialed, not every call: once a pooled
+// connection is established, rotation only takes effect the next time that connection's TLS
+// session is renegotiated or the connection is redialed.
+func WithClientCertProvider(provider func() (*tls.Certificate, error)) Option {
+	return func(o *Orderer) error {
+		o.clientCertProvider = provider
+
+		return nil
+	}
+}
+
 // WithInsecure is a functional option for the orderer.New constructor that configures the orderer's grpc insecure option
 func WithInsecure() Option {
 	return func(o *Orderer) error {
@@ -159,6 +229,12 @@ func FromOrdererConfig(ordererCfg *core.OrdererConfig) Option {
 		o.failFast = getFailFast(ordererCfg)
 		o.allowInsecure = isInsecureConnectionAllowed(ordererCfg)
 
+		clientCert, err := getClientCert(ordererCfg)
+		if err != nil {
+			return err
+		}
+		o.clientCert = clientCert
+
 		return nil
 	}
 }
@@ -209,6 +285,64 @@ func getKeepAliveOptions(ordererCfg *core.OrdererConfig) keepalive.ClientParamet
 	return kap
 }
 
+// getClientCert reads a client certificate/key pair for mutual TLS from
+// OrdererConfig.GRPCOptions["clientCert"]/["clientKey"].
+func getClientCert(ordererCfg *core.OrdererConfig) (*tls.Certificate, error) {
+	return clientCertFromGRPCOptions(ordererCfg.GRPCOptions)
+}
+
+// clientCertFromGRPCOptions reads a client certificate/key pair for mutual TLS from the given
+// GRPCOptions map. Each value may be either inline PEM content or a path to a PEM file. It
+// returns a nil certificate (and no error) when neither option is present, since client certs
+// are optional; it is an error to set exactly one of the two, since that is almost certainly a
+// misconfiguration (e.g. a typo'd key name) rather than an intentional choice.
+func clientCertFromGRPCOptions(grpcOptions map[string]interface{}) (*tls.Certificate, error) {
+	certOpt, certOk := grpcOptions["clientCert"]
+	keyOpt, keyOk := grpcOptions["clientKey"]
+
+	switch {
+	case !certOk && !keyOk:
+		return nil, nil
+	case certOk != keyOk:
+		return nil, errors.New("clientCert and clientKey must either both be set or both be omitted")
+	}
+
+	certPEM, err := pemBytesFromOption(certOpt)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid clientCert")
+	}
+
+	keyPEM, err := pemBytesFromOption(keyOpt)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid clientKey")
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load client cert/key pair")
+	}
+
+	return &cert, nil
+}
+
+// pemBytesFromOption accepts either inline PEM content or a path to a PEM file.
+func pemBytesFromOption(opt interface{}) ([]byte, error) {
+	val, ok := opt.(string)
+	if !ok {
+		return nil, errors.New("expected a string value")
+	}
+
+	if strings.Contains(val, "-----BEGIN") {
+		return []byte(val), nil
+	}
+
+	pemBytes, err := ioutil.ReadFile(val)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read PEM file")
+	}
+	return pemBytes, nil
+}
+
 func isInsecureConnectionAllowed(ordererCfg *core.OrdererConfig) bool {
 	//allowInsecure used only when protocol is missing from URL
 	allowInsecure := !urlutil.HasProtocol(ordererCfg.URL)
@@ -231,9 +365,40 @@ func (o *Orderer) conn(ctx reqContext.Context, secured bool) (*grpc.ClientConn,
 	ctx, cancel := reqContext.WithTimeout(ctx, o.dialTimeout)
 	defer cancel()
 
+	// Pooled connProviders (see NewPooledConnector) cache connections per this key rather
+	// than per-target alone, so two Orderers sharing a target but differing in secured mode,
+	// serverName or client certificate never share a connection.
+	ctx = withConnKey(ctx, o.connKey(secured))
+
 	return o.connector.DialContext(ctx, o.url, grpcOpts...)
 }
 
+// connKey identifies the secured/serverName/client certificate tuple this Orderer dials
+// target with, for use as a connection pool cache key. The TLS CA certificate is not included.
+func (o *Orderer) connKey(secured bool) string {
+	return fmt.Sprintf("%s|secured=%t|serverName=%s|clientCert=%s", o.url, secured, o.serverName, o.clientCertIdentity())
+}
+
+// clientCertIdentity distinguishes the client certificate (if any) this Orderer presents
+// during the mTLS handshake, so a pooledConnector never hands two Orderers that share a
+// target/serverName but use different client identities the same cached connection.
+//
+// Note this only distinguishes connections at dial time: once a pooled *grpc.ClientConn is
+// established, WithClientCertProvider's "rotate without rebuilding" promise is only honored
+// on that connection's next TLS handshake (e.g. after a renegotiation or reconnect), not on
+// every call, since GetClientCertificate isn't re-invoked for an already-established session.
+func (o *Orderer) clientCertIdentity() string {
+	switch {
+	case o.clientCertProvider != nil:
+		return fmt.Sprintf("provider:%p", o.clientCertProvider)
+	case o.clientCert != nil && len(o.clientCert.Certificate) > 0:
+		sum := sha256.Sum256(o.clientCert.Certificate[0])
+		return "cert:" + hex.EncodeToString(sum[:])
+	default:
+		return "none"
+	}
+}
+
 // URL Get the Orderer url. Required property for the instance objects.
 // Returns the address of the Orderer.
 func (o *Orderer) URL() string {
@@ -250,12 +415,12 @@ func (o *Orderer) sendBroadcast(ctx reqContext.Context, envelope *fab.SignedEnve
 
 	conn, err := o.conn(ctx, secured)
 	if err != nil {
-		logger.Errorf("connecting to orderer failed [%s]", err)
 		if secured && o.allowInsecure {
 			//If secured mode failed and allow insecure is enabled then retry in insecure mode
-			logger.Debug("Secured sendBroadcast failed, attempting insecured")
+			o.log.Warnf("orderer_url=%s secured=%t msg=\"secured sendBroadcast failed, attempting insecured\" error=%q", o.url, secured, err)
 			return o.sendBroadcast(ctx, envelope, false)
 		}
+		o.log.Errorf("orderer_url=%s secured=%t msg=\"connecting to orderer failed\" error=%q", o.url, secured, err)
 		rpcStatus, ok := grpcstatus.FromError(err)
 		if ok {
 			return nil, errors.WithMessage(status.NewFromGRPCStatus(rpcStatus), "connection failed")
@@ -280,7 +445,7 @@ func (o *Orderer) sendBroadcast(ctx reqContext.Context, envelope *fab.SignedEnve
 	go func() {
 		for {
 			broadcastResponse, err := broadcastStream.Recv()
-			logger.Debugf("Orderer.broadcastStream - response:%v, error:%v\n", broadcastResponse, err)
+			o.log.Debugf("Orderer.broadcastStream - response:%v, error:%v\n", broadcastResponse, err)
 			if err != nil {
 				rpcStatus, ok := grpcstatus.FromError(err)
 				if ok {
@@ -329,12 +494,12 @@ func (o *Orderer) sendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelo
 
 	conn, err := o.conn(ctx, secured)
 	if err != nil {
-		logger.Errorf("connecting to orderer failed [%s]", err)
 		if secured && o.allowInsecure {
 			//If secured mode failed and allow insecure is enabled then retry in insecure mode
-			logger.Errorf("Secured sendBroadcast failed, attempting insecured")
+			o.log.Warnf("orderer_url=%s secured=%t msg=\"secured sendDeliver failed, attempting insecured\" error=%q", o.url, secured, err)
 			return o.sendDeliver(ctx, envelope, false)
 		}
+		o.log.Errorf("orderer_url=%s secured=%t msg=\"connecting to orderer failed\" error=%q", o.url, secured, err)
 		rpcStatus, ok := grpcstatus.FromError(err)
 		if ok {
 			errs <- errors.WithMessage(status.NewFromGRPCStatus(rpcStatus), "connection failed")
@@ -348,14 +513,14 @@ func (o *Orderer) sendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelo
 	// Create atomic broadcast client
 	broadcastStream, err := ab.NewAtomicBroadcastClient(conn).Deliver(ctx)
 	if err != nil {
-		logger.Errorf("deliver failed [%s]", err)
+		o.log.Errorf("orderer_url=%s msg=\"deliver failed\" error=%q", o.url, err)
 		o.connector.ReleaseConn(conn)
 
 		errs <- errors.Wrap(err, "deliver failed")
 		return responses, errs
 	}
 	// Send block request envelope
-	logger.Debugf("Requesting blocks from ordering service")
+	o.log.Debugf("orderer_url=%s msg=\"requesting blocks from ordering service\"", o.url)
 	if err := broadcastStream.Send(&common.Envelope{
 		Payload:   envelope.Payload,
 		Signature: envelope.Signature,
@@ -369,13 +534,13 @@ func (o *Orderer) sendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelo
 	// Receive blocks from the GRPC stream and put them on the channel
 	go func() {
 		defer o.connector.ReleaseConn(conn)
-		blockStream(broadcastStream, responses, errs)
+		blockStream(o.log, broadcastStream, responses, errs)
 
 	}()
 	return responses, errs
 }
 
-func blockStream(broadcastStream ab.AtomicBroadcast_DeliverClient, responses chan *common.Block, errs chan error) {
+func blockStream(log Logger, broadcastStream ab.AtomicBroadcast_DeliverClient, responses chan *common.Block, errs chan error) {
 	for {
 		response, err := broadcastStream.Recv()
 		if err != nil {
@@ -397,7 +562,7 @@ func blockStream(broadcastStream ab.AtomicBroadcast_DeliverClient, responses cha
 
 		// Response is a requested block
 		case *ab.DeliverResponse_Block:
-			logger.Debug("Received block from ordering service")
+			log.Debugf("Received block from ordering service")
 			responses <- response.GetBlock()
 		// Unknown response
 		default:
@@ -416,4 +581,4 @@ func (*defConnector) DialContext(ctx reqContext.Context, target string, opts ...
 
 func (*defConnector) ReleaseConn(conn *grpc.ClientConn) {
 	conn.Close()
-}
\ No newline at end of file
+}
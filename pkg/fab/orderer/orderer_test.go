@@ -0,0 +1,278 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	reqContext "context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/metadata"
+
+	ab "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+func generateTestCertPEM(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate test key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "orderer-test-client"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create test certificate: %s", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM
+}
+
+func TestClientCertFromGRPCOptionsAbsent(t *testing.T) {
+	cert, err := clientCertFromGRPCOptions(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("expected no error when clientCert/clientKey are absent, got %s", err)
+	}
+	if cert != nil {
+		t.Fatal("expected a nil certificate when clientCert/clientKey are absent")
+	}
+}
+
+func TestClientCertFromGRPCOptionsOnlyCertSet(t *testing.T) {
+	certPEM, _ := generateTestCertPEM(t)
+
+	_, err := clientCertFromGRPCOptions(map[string]interface{}{"clientCert": string(certPEM)})
+	if err == nil {
+		t.Fatal("expected an error when only clientCert is set")
+	}
+}
+
+func TestClientCertFromGRPCOptionsOnlyKeySet(t *testing.T) {
+	_, keyPEM := generateTestCertPEM(t)
+
+	_, err := clientCertFromGRPCOptions(map[string]interface{}{"clientKey": string(keyPEM)})
+	if err == nil {
+		t.Fatal("expected an error when only clientKey is set")
+	}
+}
+
+func TestClientCertFromGRPCOptionsInlinePEM(t *testing.T) {
+	certPEM, keyPEM := generateTestCertPEM(t)
+
+	cert, err := clientCertFromGRPCOptions(map[string]interface{}{
+		"clientCert": string(certPEM),
+		"clientKey":  string(keyPEM),
+	})
+	if err != nil {
+		t.Fatalf("clientCertFromGRPCOptions failed: %s", err)
+	}
+	if cert == nil {
+		t.Fatal("expected a non-nil certificate")
+	}
+}
+
+func TestClientCertFromGRPCOptionsFilePaths(t *testing.T) {
+	certPEM, keyPEM := generateTestCertPEM(t)
+
+	dir, err := ioutil.TempDir("", "orderer-clientcert-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	certPath := filepath.Join(dir, "client.crt")
+	keyPath := filepath.Join(dir, "client.key")
+	if err := ioutil.WriteFile(certPath, certPEM, 0600); err != nil {
+		t.Fatalf("failed to write cert file: %s", err)
+	}
+	if err := ioutil.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		t.Fatalf("failed to write key file: %s", err)
+	}
+
+	cert, err := clientCertFromGRPCOptions(map[string]interface{}{
+		"clientCert": certPath,
+		"clientKey":  keyPath,
+	})
+	if err != nil {
+		t.Fatalf("clientCertFromGRPCOptions failed: %s", err)
+	}
+	if cert == nil {
+		t.Fatal("expected a non-nil certificate")
+	}
+}
+
+func TestClientCertFromGRPCOptionsInvalidPair(t *testing.T) {
+	certPEM, _ := generateTestCertPEM(t)
+	_, otherKeyPEM := generateTestCertPEM(t)
+
+	_, err := clientCertFromGRPCOptions(map[string]interface{}{
+		"clientCert": string(certPEM),
+		"clientKey":  string(otherKeyPEM),
+	})
+	if err == nil {
+		t.Fatal("expected an error when the cert and key don't match")
+	}
+}
+
+func TestPemBytesFromOptionRejectsNonString(t *testing.T) {
+	if _, err := pemBytesFromOption(123); err == nil {
+		t.Fatal("expected an error for a non-string option value")
+	}
+}
+
+func TestConnKeyDistinguishesClientCertIdentity(t *testing.T) {
+	cert1PEM, key1PEM := generateTestCertPEM(t)
+	cert2PEM, key2PEM := generateTestCertPEM(t)
+
+	cert1, err := tls.X509KeyPair(cert1PEM, key1PEM)
+	if err != nil {
+		t.Fatalf("failed to load test cert 1: %s", err)
+	}
+	cert2, err := tls.X509KeyPair(cert2PEM, key2PEM)
+	if err != nil {
+		t.Fatalf("failed to load test cert 2: %s", err)
+	}
+
+	o1 := &Orderer{url: "orderer.example.com:7050", clientCert: &cert1}
+	o2 := &Orderer{url: "orderer.example.com:7050", clientCert: &cert2}
+
+	if o1.connKey(true) == o2.connKey(true) {
+		t.Fatal("expected distinct conn keys for orderers presenting different client certificates")
+	}
+}
+
+func TestConnKeyDistinguishesClientCertProvider(t *testing.T) {
+	providerA := func() (*tls.Certificate, error) { return nil, nil }
+	providerB := func() (*tls.Certificate, error) { return nil, nil }
+
+	o1 := &Orderer{url: "orderer.example.com:7050", clientCertProvider: providerA}
+	o2 := &Orderer{url: "orderer.example.com:7050", clientCertProvider: providerB}
+
+	if o1.connKey(true) == o2.connKey(true) {
+		t.Fatal("expected distinct conn keys for orderers using different client cert providers")
+	}
+}
+
+func TestConnKeyStableForRepeatedClientCert(t *testing.T) {
+	certPEM, keyPEM := generateTestCertPEM(t)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("failed to load test cert: %s", err)
+	}
+
+	o1 := &Orderer{url: "orderer.example.com:7050", clientCert: &cert}
+	o2 := &Orderer{url: "orderer.example.com:7050", clientCert: &cert}
+
+	if o1.connKey(true) != o2.connKey(true) {
+		t.Fatal("expected the same conn key for orderers presenting the same client certificate")
+	}
+}
+
+func TestConnKeyNoClientCert(t *testing.T) {
+	o := &Orderer{url: "orderer.example.com:7050"}
+	if o.connKey(true) == "" {
+		t.Fatal("expected a non-empty conn key even without a client certificate")
+	}
+}
+
+// fakeLogger records Debugf calls so tests can assert o.log (rather than the package-level
+// logger) is what WithLogger actually wires into every logging call site.
+type fakeLogger struct {
+	debugfCalls int
+}
+
+func (l *fakeLogger) Debugf(format string, args ...interface{}) { l.debugfCalls++ }
+func (l *fakeLogger) Infof(format string, args ...interface{})  {}
+func (l *fakeLogger) Warnf(format string, args ...interface{})  {}
+func (l *fakeLogger) Errorf(format string, args ...interface{}) {}
+
+// fakeDeliverClient implements ab.AtomicBroadcast_DeliverClient, returning a single block
+// response followed by a success status, so blockStream can be exercised without a live
+// ordering service connection.
+type fakeDeliverClient struct {
+	responses []*ab.DeliverResponse
+	idx       int
+}
+
+func (c *fakeDeliverClient) Send(*common.Envelope) error { return nil }
+
+func (c *fakeDeliverClient) Recv() (*ab.DeliverResponse, error) {
+	if c.idx >= len(c.responses) {
+		return nil, errors.New("no more responses")
+	}
+	response := c.responses[c.idx]
+	c.idx++
+	return response, nil
+}
+
+func (c *fakeDeliverClient) Header() (metadata.MD, error) { return nil, nil }
+func (c *fakeDeliverClient) Trailer() metadata.MD         { return nil }
+func (c *fakeDeliverClient) CloseSend() error             { return nil }
+func (c *fakeDeliverClient) Context() reqContext.Context  { return reqContext.Background() }
+func (c *fakeDeliverClient) SendMsg(m interface{}) error  { return nil }
+func (c *fakeDeliverClient) RecvMsg(m interface{}) error  { return nil }
+
+func TestWithLoggerSetsOrdererLog(t *testing.T) {
+	log := &fakeLogger{}
+	o := &Orderer{}
+
+	if err := WithLogger(log)(o); err != nil {
+		t.Fatalf("WithLogger failed: %s", err)
+	}
+	if o.log != log {
+		t.Fatal("expected WithLogger to set o.log to the given Logger")
+	}
+}
+
+func TestWithLoggerRejectsNil(t *testing.T) {
+	o := &Orderer{}
+	if err := WithLogger(nil)(o); err == nil {
+		t.Fatal("expected WithLogger(nil) to return an error")
+	}
+}
+
+func TestBlockStreamUsesGivenLoggerForDeliveredBlocks(t *testing.T) {
+	client := &fakeDeliverClient{
+		responses: []*ab.DeliverResponse{
+			{Type: &ab.DeliverResponse_Block{Block: &common.Block{Header: &common.BlockHeader{Number: 1}}}},
+			{Type: &ab.DeliverResponse_Status{Status: common.Status_SUCCESS}},
+		},
+	}
+	log := &fakeLogger{}
+	responses := make(chan *common.Block, 1)
+	errs := make(chan error, 1)
+
+	blockStream(log, client, responses, errs)
+
+	if log.debugfCalls == 0 {
+		t.Fatal("expected blockStream to log delivered blocks via the given Logger")
+	}
+	if len(responses) != 1 {
+		t.Fatalf("expected one delivered block, got %d", len(responses))
+	}
+}
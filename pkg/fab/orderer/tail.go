@@ -0,0 +1,73 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	reqContext "context"
+
+	ab "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// SeekPosition identifies a point in a channel's block sequence, used as the start or stop
+// bound of an Orderer.Tail or Orderer.Fetch request. Build one with Oldest, Newest, or
+// Specified rather than constructing it directly.
+type SeekPosition struct {
+	pos *ab.SeekPosition
+}
+
+// Oldest identifies the first block in the channel's ledger.
+func Oldest() SeekPosition {
+	return SeekPosition{pos: &ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}}}
+}
+
+// Newest identifies the most recently committed block in the channel's ledger.
+func Newest() SeekPosition {
+	return SeekPosition{pos: &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}}}
+}
+
+// Specified identifies an exact block number.
+func Specified(blockNum uint64) SeekPosition {
+	return SeekPosition{pos: specifiedSeekPosition(blockNum)}
+}
+
+// Forever identifies a stop position far enough in the future that the ordering service
+// never reaches it, keeping a Tail stream open to receive new blocks as they are created.
+// Unlike Forever, Newest resolves once, at request-processing time, to the chain's current
+// height: a stream seeking up to Newest closes with a success status as soon as it catches
+// up and does not keep tailing.
+func Forever() SeekPosition {
+	return SeekPosition{pos: specifiedSeekPosition(defaultMaxBlockNumber)}
+}
+
+// Tail streams blocks for channelID from start through stop, constructing and signing the
+// seek envelope internally so callers no longer need to work with the low-level
+// common/orderer protobufs directly. Pass Forever() as stop to keep the stream open and
+// receive new blocks as they are created (SEEK_BLOCK_UNTIL_READY); use Fetch for a bounded,
+// one-shot historical read instead.
+func (o *Orderer) Tail(ctx reqContext.Context, channelID string, start, stop SeekPosition, signer Signer) (<-chan *common.Block, <-chan error) {
+	return o.seek(ctx, channelID, start, stop, signer, ab.SeekInfo_BLOCK_UNTIL_READY)
+}
+
+// Fetch performs a one-shot historical read of blocks for channelID from start through stop.
+// Unlike Tail, the ordering service fails the request immediately (SEEK_FAIL_IF_NOT_READY)
+// rather than blocking, if the requested range isn't available yet.
+func (o *Orderer) Fetch(ctx reqContext.Context, channelID string, start, stop SeekPosition, signer Signer) (<-chan *common.Block, <-chan error) {
+	return o.seek(ctx, channelID, start, stop, signer, ab.SeekInfo_FAIL_IF_NOT_READY)
+}
+
+func (o *Orderer) seek(ctx reqContext.Context, channelID string, start, stop SeekPosition, signer Signer, behavior ab.SeekInfo_SeekBehavior) (<-chan *common.Block, <-chan error) {
+	envelope, err := buildSeekEnvelope(channelID, start.pos, stop.pos, behavior, signer)
+	if err != nil {
+		errs := make(chan error, 1)
+		errs <- err
+		return nil, errs
+	}
+
+	responses, errs := o.SendDeliver(ctx, envelope)
+	return responses, errs
+}
@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	ab "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+func TestOldestProducesOldestSeekPosition(t *testing.T) {
+	pos := Oldest()
+	if _, ok := pos.pos.Type.(*ab.SeekPosition_Oldest); !ok {
+		t.Fatalf("expected a SeekPosition_Oldest, got %T", pos.pos.Type)
+	}
+}
+
+func TestNewestProducesNewestSeekPosition(t *testing.T) {
+	pos := Newest()
+	if _, ok := pos.pos.Type.(*ab.SeekPosition_Newest); !ok {
+		t.Fatalf("expected a SeekPosition_Newest, got %T", pos.pos.Type)
+	}
+}
+
+func TestSpecifiedProducesSpecifiedSeekPosition(t *testing.T) {
+	pos := Specified(42)
+	specified, ok := pos.pos.Type.(*ab.SeekPosition_Specified)
+	if !ok {
+		t.Fatalf("expected a SeekPosition_Specified, got %T", pos.pos.Type)
+	}
+	if specified.Specified.Number != 42 {
+		t.Fatalf("expected block number 42, got %d", specified.Specified.Number)
+	}
+}
+
+func TestForeverProducesFarFutureSpecifiedSeekPosition(t *testing.T) {
+	pos := Forever()
+	specified, ok := pos.pos.Type.(*ab.SeekPosition_Specified)
+	if !ok {
+		t.Fatalf("expected a SeekPosition_Specified, got %T", pos.pos.Type)
+	}
+	if specified.Specified.Number != defaultMaxBlockNumber {
+		t.Fatalf("expected block number %d, got %d", defaultMaxBlockNumber, specified.Specified.Number)
+	}
+}
+
+// seekInfoBehaviorFromEnvelope decodes the behavior baked into an envelope built by
+// buildSeekEnvelope, to verify Tail and Fetch each request the behavior they document.
+func seekInfoBehaviorFromEnvelope(t *testing.T, envelope *fab.SignedEnvelope) ab.SeekInfo_SeekBehavior {
+	t.Helper()
+
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		t.Fatalf("failed to unmarshal payload: %s", err)
+	}
+
+	seekInfo := &ab.SeekInfo{}
+	if err := proto.Unmarshal(payload.Data, seekInfo); err != nil {
+		t.Fatalf("failed to unmarshal seek info: %s", err)
+	}
+	return seekInfo.Behavior
+}
+
+// TestTailUsesBlockUntilReadyBehavior exercises the same envelope-building codepath Tail
+// uses internally (buildSeekEnvelope with ab.SeekInfo_BLOCK_UNTIL_READY), since Tail itself
+// requires a live ordering service connection to observe end-to-end.
+func TestTailUsesBlockUntilReadyBehavior(t *testing.T) {
+	signer := &fakeSigner{creator: []byte("creator"), signature: []byte("signature")}
+
+	envelope, err := buildSeekEnvelope("mychannel", Oldest().pos, Forever().pos, ab.SeekInfo_BLOCK_UNTIL_READY, signer)
+	if err != nil {
+		t.Fatalf("buildSeekEnvelope failed: %s", err)
+	}
+
+	if behavior := seekInfoBehaviorFromEnvelope(t, envelope); behavior != ab.SeekInfo_BLOCK_UNTIL_READY {
+		t.Fatalf("expected BLOCK_UNTIL_READY, got %s", behavior)
+	}
+}
+
+// TestFetchUsesFailIfNotReadyBehavior exercises the same envelope-building codepath Fetch
+// uses internally (buildSeekEnvelope with ab.SeekInfo_FAIL_IF_NOT_READY), since Fetch itself
+// requires a live ordering service connection to observe end-to-end.
+func TestFetchUsesFailIfNotReadyBehavior(t *testing.T) {
+	signer := &fakeSigner{creator: []byte("creator"), signature: []byte("signature")}
+
+	envelope, err := buildSeekEnvelope("mychannel", Oldest().pos, Specified(10).pos, ab.SeekInfo_FAIL_IF_NOT_READY, signer)
+	if err != nil {
+		t.Fatalf("buildSeekEnvelope failed: %s", err)
+	}
+
+	if behavior := seekInfoBehaviorFromEnvelope(t, envelope); behavior != ab.SeekInfo_FAIL_IF_NOT_READY {
+		t.Fatalf("expected FAIL_IF_NOT_READY, got %s", behavior)
+	}
+}
+
+func TestSeekReturnsSignerErrorWithoutDialing(t *testing.T) {
+	o := &Orderer{url: "orderer.example.com:7050", log: logger}
+	signer := &fakeSigner{serializeErr: errors.New("no identity")}
+
+	responses, errs := o.Tail(nil, "mychannel", Oldest(), Forever(), signer)
+	if responses != nil {
+		t.Fatal("expected a nil responses channel when the envelope fails to build")
+	}
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("expected a non-nil error")
+		}
+	default:
+		t.Fatal("expected an error on the errs channel")
+	}
+}